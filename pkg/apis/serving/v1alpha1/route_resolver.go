@@ -0,0 +1,124 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/knative/pkg/apis"
+)
+
+// RouteValidationResolver is consulted by TrafficTarget.Validate (when
+// present in the context) to reject Routes referencing Revisions or
+// Configurations that don't exist, or Revisions that aren't yet Ready. This
+// turns misconfigurations that would otherwise surface later during
+// reconciliation (typoed revision names, dangling references after a
+// Configuration delete) into admission-time rejections.
+type RouteValidationResolver interface {
+	// RevisionExists reports whether the named Revision exists in ns.
+	RevisionExists(ns, name string) (bool, error)
+
+	// ConfigurationExists reports whether the named Configuration exists in
+	// ns.
+	ConfigurationExists(ns, name string) (bool, error)
+
+	// RevisionReady reports whether the named Revision in ns is Ready.
+	RevisionReady(ns, name string) (bool, error)
+}
+
+// routeValidationResolverKey is the context.Context key for the
+// RouteValidationResolver optionally carried by WithRouteValidationResolver.
+type routeValidationResolverKey struct{}
+
+// WithRouteValidationResolver returns a context carrying r, so that
+// TrafficTarget.Validate can consult it to validate Revision/Configuration
+// existence and readiness at admission time. Callers that don't attach a
+// resolver (unit tests, CLI dry-run) get the current syntactic-only
+// validation.
+func WithRouteValidationResolver(ctx context.Context, r RouteValidationResolver) context.Context {
+	return context.WithValue(ctx, routeValidationResolverKey{}, r)
+}
+
+// routeValidationResolverFrom returns the RouteValidationResolver attached to
+// ctx by WithRouteValidationResolver, or nil if none was attached.
+func routeValidationResolverFrom(ctx context.Context) RouteValidationResolver {
+	r, _ := ctx.Value(routeValidationResolverKey{}).(RouteValidationResolver)
+	return r
+}
+
+// routeNamespaceKey is the context.Context key for the namespace of the
+// Route currently being validated, set by Route.Validate so that nested
+// TrafficTarget validation can resolve Revisions/Configurations relative to
+// it.
+type routeNamespaceKey struct{}
+
+func withRouteNamespace(ctx context.Context, ns string) context.Context {
+	return context.WithValue(ctx, routeNamespaceKey{}, ns)
+}
+
+func routeNamespaceFrom(ctx context.Context) string {
+	ns, _ := ctx.Value(routeNamespaceKey{}).(string)
+	return ns
+}
+
+// checkRevisionExists consults the RouteValidationResolver attached to ctx,
+// if any, to confirm that the named Revision exists and is Ready. It is a
+// no-op when no resolver is attached (unit tests, CLI dry-run).
+func checkRevisionExists(ctx context.Context, name string) *apis.FieldError {
+	resolver := routeValidationResolverFrom(ctx)
+	if resolver == nil {
+		return nil
+	}
+	ns := routeNamespaceFrom(ctx)
+
+	ok, err := resolver.RevisionExists(ns, name)
+	if err != nil {
+		return &apis.FieldError{Message: fmt.Sprintf("failed to resolve revisionName %q: %v", name, err), Paths: []string{"revisionName"}}
+	}
+	if !ok {
+		return &apis.FieldError{Message: fmt.Sprintf("revisionName %q does not exist", name), Paths: []string{"revisionName"}}
+	}
+
+	ready, err := resolver.RevisionReady(ns, name)
+	if err != nil {
+		return &apis.FieldError{Message: fmt.Sprintf("failed to resolve readiness of revisionName %q: %v", name, err), Paths: []string{"revisionName"}}
+	}
+	if !ready {
+		return &apis.FieldError{Message: fmt.Sprintf("revisionName %q is not yet Ready", name), Paths: []string{"revisionName"}}
+	}
+	return nil
+}
+
+// checkConfigurationExists consults the RouteValidationResolver attached to
+// ctx, if any, to confirm that the named Configuration exists. It is a no-op
+// when no resolver is attached (unit tests, CLI dry-run).
+func checkConfigurationExists(ctx context.Context, name string) *apis.FieldError {
+	resolver := routeValidationResolverFrom(ctx)
+	if resolver == nil {
+		return nil
+	}
+
+	ok, err := resolver.ConfigurationExists(routeNamespaceFrom(ctx), name)
+	if err != nil {
+		return &apis.FieldError{Message: fmt.Sprintf("failed to resolve configurationName %q: %v", name, err), Paths: []string{"configurationName"}}
+	}
+	if !ok {
+		return &apis.FieldError{Message: fmt.Sprintf("configurationName %q does not exist", name), Paths: []string{"configurationName"}}
+	}
+	return nil
+}
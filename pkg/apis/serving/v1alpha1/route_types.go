@@ -0,0 +1,238 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/knative/pkg/apis/duck"
+	duckv1beta1 "github.com/knative/pkg/apis/duck/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Route is responsible for configuring ingress over a collection of Revisions.
+// Some of the Revisions a Route distributes traffic over may be specified by
+// referencing the Configuration responsible for creating them; in these cases
+// the Route is additionally responsible for monitoring the Configuration for
+// "latest ready" revision changes, and smoothly rolling out latest revisions.
+type Route struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state of the Route (from the client).
+	Spec RouteSpec `json:"spec,omitempty"`
+
+	// Status communicates the observed state of the Route (from the controller).
+	Status RouteStatus `json:"status,omitempty"`
+}
+
+// RouteSpec holds the desired state of the Route (from the client).
+type RouteSpec struct {
+	// DeprecatedGeneration was used prior in Kubernetes versions < 1.11
+	// when metadata.generation was not being incremented by the api server
+	//
+	// This property will be dropped in future Knative releases and should
+	// not be used - use metadata.generation
+	//
+	// +optional
+	DeprecatedGeneration int64 `json:"generation,omitempty"`
+
+	// Traffic specifies how to distribute traffic over a collection of
+	// revisions and configurations.
+	// +optional
+	Traffic []TrafficTarget `json:"traffic,omitempty"`
+
+	// Rollout optionally describes a progressive traffic shift towards
+	// TargetRevision, evolving the effective Traffic split over time
+	// according to Steps rather than requiring the client to script
+	// percentage edits to Traffic directly.
+	// +optional
+	Rollout *RolloutSpec `json:"rollout,omitempty"`
+}
+
+// RolloutSpec describes a progressive traffic shift towards a target
+// revision, driven by a reconciler that advances through Steps over time.
+type RolloutSpec struct {
+	// TargetRevision names the revision that traffic is being progressively
+	// shifted towards. It must not also appear as a Traffic entry; Rollout
+	// owns its percentage of traffic for the duration of the rollout.
+	TargetRevision string `json:"targetRevision"`
+
+	// Steps describes the schedule of percentages TargetRevision should
+	// advance through, in order. Step percentages must be monotonically
+	// increasing.
+	Steps []RolloutStep `json:"steps,omitempty"`
+
+	// MaxSurge is the maximum additional percentage of capacity that may be
+	// temporarily provisioned for TargetRevision while stepping up, above
+	// and beyond the percentage called for by the current Step.
+	// +optional
+	MaxSurge string `json:"maxSurge,omitempty"`
+
+	// AbortOnFailure indicates that the rollout should automatically revert
+	// TargetRevision's traffic to 0 if a Step's AnalysisRef reports failure.
+	// +optional
+	AbortOnFailure bool `json:"abortOnFailure,omitempty"`
+}
+
+// RolloutStep describes a single step of a progressive traffic shift.
+type RolloutStep struct {
+	// Percent is the percentage of traffic TargetRevision should hold once
+	// this step is reached.
+	Percent int `json:"percent"`
+
+	// PauseDuration is how long the rollout should hold at Percent before
+	// advancing to the next step, expressed as a duration string parseable
+	// by time.ParseDuration (e.g. "5m", "1h").
+	// +optional
+	PauseDuration string `json:"pauseDuration,omitempty"`
+
+	// AnalysisRef optionally references an object (e.g. a metrics analysis
+	// template) that gates advancement past this step.
+	// +optional
+	AnalysisRef *corev1.ObjectReference `json:"analysisRef,omitempty"`
+}
+
+// TrafficTarget holds a single entry of the routing table that controls
+// what percentage of traffic goes to a particular revision or configuration.
+type TrafficTarget struct {
+	// Name is optionally used to expose a dedicated hostname for referencing
+	// this target exclusively.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// ConfigurationName of a configuration to whose latest revision we will
+	// send this portion of traffic. When the "status.latestReadyRevisionName"
+	// of the referenced configuration changes, we will automatically migrate
+	// traffic from the prior "latest ready" revision to the new one.
+	// This field is mutually exclusive with RevisionName.
+	// +optional
+	ConfigurationName string `json:"configurationName,omitempty"`
+
+	// RevisionName of a specific revision to which to send this portion of
+	// traffic. This is mutually exclusive with ConfigurationName.
+	// +optional
+	RevisionName string `json:"revisionName,omitempty"`
+
+	// LatestRevision may be optionally provided to indicate that the latest
+	// ready Revision of the Configuration named by ConfigurationName should
+	// be used for this traffic target. When this field is true, RevisionName
+	// must be empty and ConfigurationName must be set. This field is
+	// mutually exclusive with RevisionName.
+	// +optional
+	LatestRevision bool `json:"latestRevision,omitempty"`
+
+	// Tag is used to expose a dedicated URL for referencing this target
+	// exclusively, in addition to the Route's main URL. Tags must be
+	// unique across all TrafficTarget entries of a Route and must be valid
+	// DNS-1035 labels, since they are used to construct a subdomain of the
+	// Route's domain (e.g. "latest-mysvc.default.example.com").
+	// +optional
+	Tag string `json:"tag,omitempty"`
+
+	// Percent indicates that percentage based routing should be used and
+	// the value indicates the percent of traffic that is be routed to this
+	// Revision or Configuration. `0` (zero) mean no traffic, `100` means all
+	// traffic.
+	// When percentage based routing is being used the follow rules apply:
+	// - the sum of the percent values of targets without a Match must equal
+	//   100, less the final Step's Percent when a Rollout is set
+	// - a value of 0 (zero) is acceptable
+	Percent int `json:"percent"`
+
+	// Match optionally restricts this target to only receive the portion of
+	// traffic whose request matches the given header and/or Host rules.
+	// When Match is set, Percent may be zero, since matched requests are
+	// routed independently of the percentage-based split.
+	// +optional
+	Match *TrafficMatch `json:"match,omitempty"`
+
+	// URL displays the URL for accessing named traffic targets. URL is
+	// displayed in status, and is disallowed on spec except when the target
+	// carries a Tag, in which case the controller populates it with the
+	// tag's dedicated subdomain URL. URL must contain a scheme (e.g.
+	// http://) and a hostname, but may not contain anything else (e.g.
+	// basic auth, url path, etc.)
+	// +optional
+	URL string `json:"url,omitempty"`
+}
+
+// TrafficMatch restricts a TrafficTarget to requests matching the given
+// header and/or Host rules, for use by downstream ingress translators that
+// support header-based routing (e.g. Istio VirtualService, Gloo).
+type TrafficMatch struct {
+	// Headers maps a header name to the rule its value must satisfy for a
+	// request to be routed to this target.
+	// +optional
+	Headers map[string]HeaderMatch `json:"headers,omitempty"`
+
+	// Host, if set, requires the inbound request's Host to equal this value
+	// for a request to be routed to this target.
+	// +optional
+	Host string `json:"host,omitempty"`
+}
+
+// HeaderMatch describes how a single header's value is matched. Exactly one
+// of Exact, Prefix, or Regex must be set.
+type HeaderMatch struct {
+	// Exact requires the header value to equal this string exactly.
+	// +optional
+	Exact string `json:"exact,omitempty"`
+
+	// Prefix requires the header value to begin with this string.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Regex requires the header value to match this regular expression.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+}
+
+// RouteStatus communicates the observed state of the Route (from the
+// controller).
+type RouteStatus struct {
+	duckv1beta1.Status `json:",inline"`
+
+	// Domain holds the top-level domain that will distribute traffic over
+	// the provided targets.
+	// +optional
+	Domain string `json:"domain,omitempty"`
+
+	// DomainInternal holds the top-level domain that will distribute traffic
+	// over the provided targets from inside the cluster.
+	// +optional
+	DomainInternal string `json:"domainInternal,omitempty"`
+
+	// Traffic holds the configured traffic distribution, reflecting the
+	// observed state of the Route.
+	// +optional
+	Traffic []TrafficTarget `json:"traffic,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RouteList is a list of Route resources.
+type RouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Route `json:"items"`
+}
+
+var _ duck.Implementable = (*Route)(nil)
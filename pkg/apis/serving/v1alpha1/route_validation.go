@@ -19,6 +19,11 @@ package v1alpha1
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/knative/pkg/apis"
 	"github.com/knative/serving/pkg/apis/serving"
@@ -26,8 +31,13 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation"
 )
 
+// httpTokenRE matches a valid HTTP header field-name token, per RFC 7230
+// section 3.2.6.
+var httpTokenRE = regexp.MustCompile(`^[!#$%&'*+\-.^_` + "`" + `|~0-9A-Za-z]+$`)
+
 func (r *Route) Validate(ctx context.Context) *apis.FieldError {
 	errs := serving.ValidateObjectMetadata(r.GetObjectMeta()).ViaField("metadata")
+	ctx = withRouteNamespace(ctx, r.Namespace)
 	errs = errs.Also(r.Spec.Validate(apis.WithinSpec(ctx)).ViaField("spec"))
 	return errs
 }
@@ -44,11 +54,43 @@ func (rs *RouteSpec) Validate(ctx context.Context) *apis.FieldError {
 	// Track the targets of named TrafficTarget entries (to detect duplicates).
 	trafficMap := make(map[string]int)
 
+	// Track the tags of tagged TrafficTarget entries (to detect duplicates).
+	tagMap := make(map[string]int)
+
+	// Track the revisions/configurations already claimed by a Traffic entry,
+	// so a Rollout can reject a TargetRevision that collides with one.
+	trafficRevisions := make(map[string]struct{})
+
 	percentSum := 0
 	for i, tt := range rs.Traffic {
 		errs = errs.Also(tt.Validate(ctx).ViaFieldIndex("traffic", i))
 
-		percentSum += tt.Percent
+		// Match-based targets are routed independently of the percentage
+		// split, so they don't participate in the percent sum.
+		if tt.Match == nil {
+			percentSum += tt.Percent
+		}
+
+		if tt.RevisionName != "" {
+			trafficRevisions[tt.RevisionName] = struct{}{}
+		}
+		if tt.ConfigurationName != "" {
+			trafficRevisions[tt.ConfigurationName] = struct{}{}
+		}
+
+		if tt.Tag != "" {
+			if ent, ok := tagMap[tt.Tag]; !ok {
+				tagMap[tt.Tag] = i
+			} else {
+				errs = errs.Also(&apis.FieldError{
+					Message: fmt.Sprintf("Multiple definitions for tag %q", tt.Tag),
+					Paths: []string{
+						fmt.Sprintf("traffic[%d].tag", ent),
+						fmt.Sprintf("traffic[%d].tag", i),
+					},
+				})
+			}
+		}
 
 		if tt.Name == "" {
 			// No Name field, so skip the uniqueness check.
@@ -71,12 +113,109 @@ func (rs *RouteSpec) Validate(ctx context.Context) *apis.FieldError {
 		}
 	}
 
-	if percentSum != 100 {
+	// When a Rollout is present, it owns the percentage up to its final
+	// step's target on behalf of TargetRevision; the static Traffic entries
+	// only need to make up the remainder.
+	wantPercentSum := 100
+	if rs.Rollout != nil && len(rs.Rollout.Steps) > 0 {
+		wantPercentSum = 100 - rs.Rollout.Steps[len(rs.Rollout.Steps)-1].Percent
+	}
+
+	if percentSum != wantPercentSum {
 		errs = errs.Also(&apis.FieldError{
-			Message: fmt.Sprintf("Traffic targets sum to %d, want 100", percentSum),
+			Message: fmt.Sprintf("Traffic targets sum to %d, want %d", percentSum, wantPercentSum),
 			Paths:   []string{"traffic"},
 		})
 	}
+
+	if rs.Rollout != nil {
+		errs = errs.Also(rs.Rollout.Validate(ctx, trafficRevisions).ViaField("rollout"))
+	}
+	return errs
+}
+
+// Validate verifies that RolloutSpec is properly configured.
+func (r *RolloutSpec) Validate(ctx context.Context, trafficRevisions map[string]struct{}) *apis.FieldError {
+	var errs *apis.FieldError
+	if r.TargetRevision == "" {
+		errs = errs.Also(apis.ErrMissingField("targetRevision"))
+	} else if _, ok := trafficRevisions[r.TargetRevision]; ok {
+		errs = errs.Also(&apis.FieldError{
+			Message: fmt.Sprintf("TargetRevision %q must not also be referenced by a Traffic entry", r.TargetRevision),
+			Paths:   []string{"targetRevision"},
+		})
+	}
+
+	if r.MaxSurge != "" && !isValidPercentValue(r.MaxSurge) {
+		errs = errs.Also(apis.ErrInvalidValue(r.MaxSurge, "maxSurge"))
+	}
+
+	lastPercent := -1
+	for i, step := range r.Steps {
+		if step.Percent < 0 || step.Percent > 100 {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(step.Percent, 0, 100, fmt.Sprintf("steps[%d].percent", i)))
+		} else if step.Percent <= lastPercent {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("Step percent %d does not monotonically increase from previous step's %d", step.Percent, lastPercent),
+				Paths:   []string{fmt.Sprintf("steps[%d].percent", i)},
+			})
+		}
+		lastPercent = step.Percent
+
+		if step.PauseDuration != "" {
+			if _, err := time.ParseDuration(step.PauseDuration); err != nil {
+				errs = errs.Also(apis.ErrInvalidValue(step.PauseDuration, fmt.Sprintf("steps[%d].pauseDuration", i)))
+			}
+		}
+	}
+	return errs
+}
+
+// isValidPercentValue reports whether s is a valid MaxSurge value: an
+// integer percentage (e.g. "25") or a "%"-suffixed integer (e.g. "25%"),
+// in the range [0, 100].
+func isValidPercentValue(s string) bool {
+	s = strings.TrimSuffix(s, "%")
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return false
+	}
+	return n >= 0 && n <= 100
+}
+
+// Validate verifies that TrafficMatch is properly configured.
+func (m *TrafficMatch) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	for name, match := range m.Headers {
+		if !httpTokenRE.MatchString(name) {
+			errs = errs.Also(apis.ErrInvalidKeyName(name, "headers"))
+			continue
+		}
+		errs = errs.Also(match.Validate(ctx).ViaFieldKey("headers", name))
+	}
+	return errs
+}
+
+// Validate verifies that HeaderMatch is properly configured.
+func (h *HeaderMatch) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	set := 0
+	for _, v := range []string{h.Exact, h.Prefix, h.Regex} {
+		if v != "" {
+			set++
+		}
+	}
+	switch {
+	case set > 1:
+		errs = apis.ErrMultipleOneOf("exact", "prefix", "regex")
+	case set == 0:
+		errs = apis.ErrMissingOneOf("exact", "prefix", "regex")
+	}
+	if h.Regex != "" {
+		if _, err := regexp.Compile(h.Regex); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(h.Regex, "regex"))
+		}
+	}
 	return errs
 }
 
@@ -84,15 +223,29 @@ func (rs *RouteSpec) Validate(ctx context.Context) *apis.FieldError {
 func (tt *TrafficTarget) Validate(ctx context.Context) *apis.FieldError {
 	var errs *apis.FieldError
 	switch {
+	case tt.LatestRevision && tt.RevisionName != "":
+		errs = apis.ErrMultipleOneOf("revisionName", "latestRevision")
+	case tt.LatestRevision:
+		if tt.ConfigurationName == "" {
+			errs = apis.ErrMissingField("configurationName")
+		} else if verrs := validation.IsQualifiedName(tt.ConfigurationName); len(verrs) > 0 {
+			errs = apis.ErrInvalidKeyName(tt.ConfigurationName, "configurationName", verrs...)
+		} else {
+			errs = errs.Also(checkConfigurationExists(ctx, tt.ConfigurationName))
+		}
 	case tt.RevisionName != "" && tt.ConfigurationName != "":
 		errs = apis.ErrMultipleOneOf("revisionName", "configurationName")
 	case tt.RevisionName != "":
 		if verrs := validation.IsQualifiedName(tt.RevisionName); len(verrs) > 0 {
 			errs = apis.ErrInvalidKeyName(tt.RevisionName, "revisionName", verrs...)
+		} else {
+			errs = errs.Also(checkRevisionExists(ctx, tt.RevisionName))
 		}
 	case tt.ConfigurationName != "":
 		if verrs := validation.IsQualifiedName(tt.ConfigurationName); len(verrs) > 0 {
 			errs = apis.ErrInvalidKeyName(tt.ConfigurationName, "configurationName", verrs...)
+		} else {
+			errs = errs.Also(checkConfigurationExists(ctx, tt.ConfigurationName))
 		}
 	default:
 		errs = apis.ErrMissingOneOf("revisionName", "configurationName")
@@ -100,7 +253,24 @@ func (tt *TrafficTarget) Validate(ctx context.Context) *apis.FieldError {
 	if tt.Percent < 0 || tt.Percent > 100 {
 		errs = errs.Also(apis.ErrOutOfBoundsValue(tt.Percent, 0, 100, "percent"))
 	}
-	if tt.URL != "" {
+	if tt.Match != nil {
+		errs = errs.Also(tt.Match.Validate(ctx).ViaField("match"))
+	}
+	// A tagged target is allowed to carry Percent: 0, since it is reachable
+	// via its dedicated tag URL without participating in the traffic split.
+	if tt.Tag != "" {
+		if verrs := validation.IsDNS1035Label(tt.Tag); len(verrs) > 0 {
+			errs = errs.Also(apis.ErrInvalidValue(tt.Tag, "tag"))
+		}
+		// The URL field is populated by the controller as status output for
+		// tagged targets, but a client-supplied value must still look like a
+		// URL: a scheme and a hostname, and nothing else.
+		if tt.URL != "" {
+			if u, err := url.Parse(tt.URL); err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+				errs = errs.Also(apis.ErrInvalidValue(tt.URL, "url"))
+			}
+		}
+	} else if tt.URL != "" {
 		errs = errs.Also(apis.ErrDisallowedFields("url"))
 	}
 	return errs
@@ -0,0 +1,447 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/knative/pkg/apis"
+)
+
+func TestTrafficTargetValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		tt   TrafficTarget
+		want *apis.FieldError
+	}{{
+		name: "valid percent target",
+		tt: TrafficTarget{
+			RevisionName: "foo",
+			Percent:      100,
+		},
+		want: nil,
+	}, {
+		name: "tagged target with zero percent is valid",
+		tt: TrafficTarget{
+			RevisionName: "foo",
+			Tag:          "latest",
+			Percent:      0,
+		},
+		want: nil,
+	}, {
+		name: "invalid tag",
+		tt: TrafficTarget{
+			RevisionName: "foo",
+			Tag:          "Not_A_DNS1035_Label",
+			Percent:      0,
+		},
+		want: apis.ErrInvalidValue("Not_A_DNS1035_Label", "tag"),
+	}, {
+		name: "url disallowed without tag",
+		tt: TrafficTarget{
+			RevisionName: "foo",
+			Percent:      100,
+			URL:          "http://foo.default.example.com",
+		},
+		want: apis.ErrDisallowedFields("url"),
+	}, {
+		name: "well-formed url allowed with tag",
+		tt: TrafficTarget{
+			RevisionName: "foo",
+			Tag:          "latest",
+			Percent:      0,
+			URL:          "http://latest-foo.default.example.com",
+		},
+		want: nil,
+	}, {
+		name: "url with a path is rejected even with tag",
+		tt: TrafficTarget{
+			RevisionName: "foo",
+			Tag:          "latest",
+			Percent:      0,
+			URL:          "http://latest-foo.default.example.com/path",
+		},
+		want: apis.ErrInvalidValue("http://latest-foo.default.example.com/path", "url"),
+	}, {
+		name: "latestRevision shorthand is valid with configurationName",
+		tt: TrafficTarget{
+			ConfigurationName: "foo",
+			LatestRevision:    true,
+			Percent:           100,
+		},
+		want: nil,
+	}, {
+		name: "latestRevision with revisionName is rejected",
+		tt: TrafficTarget{
+			RevisionName:   "foo",
+			LatestRevision: true,
+			Percent:        100,
+		},
+		want: apis.ErrMultipleOneOf("revisionName", "latestRevision"),
+	}, {
+		name: "latestRevision without configurationName is rejected",
+		tt: TrafficTarget{
+			LatestRevision: true,
+			Percent:        100,
+		},
+		want: apis.ErrMissingField("configurationName"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.tt.Validate(context.Background())
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("Validate (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestRouteSpecRolloutValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		rs      *RouteSpec
+		wantErr bool
+	}{{
+		name: "valid rollout with static traffic making up the remainder",
+		rs: &RouteSpec{
+			Traffic: []TrafficTarget{{
+				RevisionName: "stable",
+				Percent:      75,
+			}},
+			Rollout: &RolloutSpec{
+				TargetRevision: "canary",
+				Steps: []RolloutStep{
+					{Percent: 10, PauseDuration: "5m"},
+					{Percent: 25, PauseDuration: "10m"},
+				},
+			},
+		},
+		wantErr: false,
+	}, {
+		name: "targetRevision colliding with a traffic entry's revisionName is rejected",
+		rs: &RouteSpec{
+			Traffic: []TrafficTarget{{
+				RevisionName: "canary",
+				Percent:      90,
+			}},
+			Rollout: &RolloutSpec{
+				TargetRevision: "canary",
+				Steps: []RolloutStep{
+					{Percent: 10},
+				},
+			},
+		},
+		wantErr: true,
+	}, {
+		name: "non-monotonic steps are rejected",
+		rs: &RouteSpec{
+			Traffic: []TrafficTarget{{
+				RevisionName: "stable",
+				Percent:      90,
+			}},
+			Rollout: &RolloutSpec{
+				TargetRevision: "canary",
+				Steps: []RolloutStep{
+					{Percent: 25},
+					{Percent: 10},
+				},
+			},
+		},
+		wantErr: true,
+	}, {
+		name: "unparseable pauseDuration is rejected",
+		rs: &RouteSpec{
+			Traffic: []TrafficTarget{{
+				RevisionName: "stable",
+				Percent:      75,
+			}},
+			Rollout: &RolloutSpec{
+				TargetRevision: "canary",
+				Steps: []RolloutStep{
+					{Percent: 25, PauseDuration: "not-a-duration"},
+				},
+			},
+		},
+		wantErr: true,
+	}, {
+		name: "percentage maxSurge is valid",
+		rs: &RouteSpec{
+			Traffic: []TrafficTarget{{
+				RevisionName: "stable",
+				Percent:      75,
+			}},
+			Rollout: &RolloutSpec{
+				TargetRevision: "canary",
+				MaxSurge:       "25%",
+				Steps: []RolloutStep{
+					{Percent: 25},
+				},
+			},
+		},
+		wantErr: false,
+	}, {
+		name: "duration-shaped maxSurge is rejected",
+		rs: &RouteSpec{
+			Traffic: []TrafficTarget{{
+				RevisionName: "stable",
+				Percent:      75,
+			}},
+			Rollout: &RolloutSpec{
+				TargetRevision: "canary",
+				MaxSurge:       "30s",
+				Steps: []RolloutStep{
+					{Percent: 25},
+				},
+			},
+		},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.rs.Validate(context.Background())
+			if gotErr := got != nil; gotErr != test.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", got, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestHeaderMatchValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		h       HeaderMatch
+		wantErr bool
+	}{{
+		name:    "exact only is valid",
+		h:       HeaderMatch{Exact: "v1"},
+		wantErr: false,
+	}, {
+		name:    "prefix only is valid",
+		h:       HeaderMatch{Prefix: "v"},
+		wantErr: false,
+	}, {
+		name:    "regex only is valid",
+		h:       HeaderMatch{Regex: "^v[0-9]+$"},
+		wantErr: false,
+	}, {
+		name:    "exact and regex together is rejected",
+		h:       HeaderMatch{Exact: "v1", Regex: "^v[0-9]+$"},
+		wantErr: true,
+	}, {
+		name:    "exact and prefix together is rejected",
+		h:       HeaderMatch{Exact: "v1", Prefix: "v"},
+		wantErr: true,
+	}, {
+		name:    "prefix and regex together is rejected",
+		h:       HeaderMatch{Prefix: "v", Regex: "^v[0-9]+$"},
+		wantErr: true,
+	}, {
+		name:    "none set is rejected",
+		h:       HeaderMatch{},
+		wantErr: true,
+	}, {
+		name:    "unparseable regex is rejected",
+		h:       HeaderMatch{Regex: "("},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.h.Validate(context.Background())
+			if gotErr := got != nil; gotErr != test.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", got, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestTrafficMatchValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       TrafficMatch
+		wantErr bool
+	}{{
+		name: "valid header name",
+		m: TrafficMatch{
+			Headers: map[string]HeaderMatch{
+				"X-Canary": {Exact: "true"},
+			},
+		},
+		wantErr: false,
+	}, {
+		name: "header name with an invalid token character is rejected",
+		m: TrafficMatch{
+			Headers: map[string]HeaderMatch{
+				"X Canary": {Exact: "true"},
+			},
+		},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.m.Validate(context.Background())
+			if gotErr := got != nil; gotErr != test.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", got, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestRouteSpecPercentSumExcludesMatchTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		rs      *RouteSpec
+		wantErr bool
+	}{{
+		name: "match-based target's percent is excluded from the sum",
+		rs: &RouteSpec{
+			Traffic: []TrafficTarget{{
+				RevisionName: "stable",
+				Percent:      100,
+			}, {
+				RevisionName: "canary",
+				Match:        &TrafficMatch{Host: "x"},
+				Percent:      20,
+			}},
+		},
+		wantErr: false,
+	}, {
+		name: "percentage-only targets still must sum to 100",
+		rs: &RouteSpec{
+			Traffic: []TrafficTarget{{
+				RevisionName: "stable",
+				Percent:      80,
+			}, {
+				RevisionName: "canary",
+				Match:        &TrafficMatch{Host: "x"},
+				Percent:      20,
+			}},
+		},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.rs.Validate(context.Background())
+			if gotErr := got != nil; gotErr != test.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", got, test.wantErr)
+			}
+		})
+	}
+}
+
+// fakeRouteValidationResolver is a RouteValidationResolver backed by fixed
+// sets of existing/ready names, for use in tests.
+type fakeRouteValidationResolver struct {
+	revisions      map[string]bool // name -> ready
+	configurations map[string]bool
+}
+
+func (f *fakeRouteValidationResolver) RevisionExists(ns, name string) (bool, error) {
+	_, ok := f.revisions[name]
+	return ok, nil
+}
+
+func (f *fakeRouteValidationResolver) RevisionReady(ns, name string) (bool, error) {
+	return f.revisions[name], nil
+}
+
+func (f *fakeRouteValidationResolver) ConfigurationExists(ns, name string) (bool, error) {
+	_, ok := f.configurations[name]
+	return ok, nil
+}
+
+func TestTrafficTargetValidationWithResolver(t *testing.T) {
+	resolver := &fakeRouteValidationResolver{
+		revisions: map[string]bool{
+			"ready-rev":     true,
+			"not-ready-rev": false,
+		},
+		configurations: map[string]bool{
+			"my-config": true,
+		},
+	}
+
+	tests := []struct {
+		name    string
+		tt      TrafficTarget
+		wantErr bool
+	}{{
+		name:    "existing, ready revision is valid",
+		tt:      TrafficTarget{RevisionName: "ready-rev", Percent: 100},
+		wantErr: false,
+	}, {
+		name:    "nonexistent revision is rejected",
+		tt:      TrafficTarget{RevisionName: "no-such-rev", Percent: 100},
+		wantErr: true,
+	}, {
+		name:    "not-yet-ready revision is rejected",
+		tt:      TrafficTarget{RevisionName: "not-ready-rev", Percent: 100},
+		wantErr: true,
+	}, {
+		name:    "existing configuration is valid",
+		tt:      TrafficTarget{ConfigurationName: "my-config", Percent: 100},
+		wantErr: false,
+	}, {
+		name:    "nonexistent configuration is rejected",
+		tt:      TrafficTarget{ConfigurationName: "no-such-config", Percent: 100},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := WithRouteValidationResolver(context.Background(), resolver)
+			got := test.tt.Validate(ctx)
+			if gotErr := got != nil; gotErr != test.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", got, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestTrafficTargetValidationWithoutResolver(t *testing.T) {
+	// With no resolver attached to the context, a syntactically valid but
+	// nonexistent revision name must still pass (syntactic-only validation).
+	tt := TrafficTarget{RevisionName: "no-such-rev", Percent: 100}
+	if got := tt.Validate(context.Background()); got != nil {
+		t.Errorf("Validate() = %v, want nil", got)
+	}
+}
+
+func TestRouteSpecTagUniqueness(t *testing.T) {
+	rs := &RouteSpec{
+		Traffic: []TrafficTarget{{
+			RevisionName: "foo",
+			Tag:          "latest",
+			Percent:      50,
+		}, {
+			RevisionName: "bar",
+			Tag:          "latest",
+			Percent:      50,
+		}},
+	}
+
+	got := rs.Validate(context.Background())
+	if got == nil {
+		t.Fatal("Validate() = nil, want an error for duplicate tags")
+	}
+}